@@ -0,0 +1,121 @@
+package cln16sidh
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+func TestPrimeFieldElementBytesRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(10))
+	for i := 0; i < 64; i++ {
+		var x PrimeFieldElement
+		x.FromBytes(randCanonicalFp751Bytes(r))
+
+		var buf [fp751NumBytes]byte
+		x.ToBytes(buf[:])
+
+		var y PrimeFieldElement
+		if err := y.FromBytes(buf[:]); err != nil {
+			t.Fatalf("FromBytes: %v", err)
+		}
+
+		var buf2 [fp751NumBytes]byte
+		y.ToBytes(buf2[:])
+		if !bytes.Equal(buf[:], buf2[:]) {
+			t.Fatalf("round trip did not reproduce the original encoding")
+		}
+	}
+}
+
+func TestExtensionFieldElementBytesRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	for i := 0; i < 64; i++ {
+		var a, b PrimeFieldElement
+		a.FromBytes(randCanonicalFp751Bytes(r))
+		b.FromBytes(randCanonicalFp751Bytes(r))
+		x := ExtensionFieldElement{a: a.a, b: b.a}
+
+		var buf [188]byte
+		x.ToBytes(buf[:])
+
+		var y ExtensionFieldElement
+		if err := y.FromBytes(buf[:]); err != nil {
+			t.Fatalf("FromBytes: %v", err)
+		}
+
+		var buf2 [188]byte
+		y.ToBytes(buf2[:])
+		if !bytes.Equal(buf[:], buf2[:]) {
+			t.Fatalf("round trip did not reproduce the original encoding")
+		}
+	}
+}
+
+// TestPrimeFieldElementKnownAnswers pins the encodings of a few field
+// elements whose canonical byte representation is easy to check by hand, so
+// a future change to ToBytes/FromBytes that silently alters the wire format
+// gets caught.
+func TestPrimeFieldElementKnownAnswers(t *testing.T) {
+	cases := []struct {
+		name string
+		val  uint64
+	}{
+		{"zero", 0},
+		{"one", 1},
+		{"small", 12345},
+	}
+
+	for _, c := range cases {
+		var x PrimeFieldElement
+		x.SetUint64(c.val)
+
+		var buf [fp751NumBytes]byte
+		x.ToBytes(buf[:])
+
+		want := make([]byte, fp751NumBytes)
+		want[0] = byte(c.val)
+		want[1] = byte(c.val >> 8)
+		want[2] = byte(c.val >> 16)
+		want[3] = byte(c.val >> 24)
+		want[4] = byte(c.val >> 32)
+		want[5] = byte(c.val >> 40)
+		want[6] = byte(c.val >> 48)
+		want[7] = byte(c.val >> 56)
+
+		if !bytes.Equal(buf[:], want) {
+			t.Fatalf("%s: got % x, want % x", c.name, buf, want)
+		}
+
+		var y PrimeFieldElement
+		if err := y.FromBytes(buf[:]); err != nil {
+			t.Fatalf("%s: FromBytes: %v", c.name, err)
+		}
+		if !x.VartimeEq(&y) {
+			t.Fatalf("%s: FromBytes(ToBytes(x)) != x", c.name)
+		}
+	}
+}
+
+// TestPrimeFieldElementFromBytesRejectsNonCanonical checks that an encoding
+// of p751 itself -- one past the largest canonical value, p751-1 -- is
+// rejected rather than silently wrapping.
+func TestPrimeFieldElementFromBytesRejectsNonCanonical(t *testing.T) {
+	var buf [fp751NumBytes]byte
+	fp751ToBytes(buf[:], &fp751P)
+
+	var x PrimeFieldElement
+	if err := x.FromBytes(buf[:]); err == nil {
+		t.Fatalf("expected FromBytes to reject the encoding of p751 itself")
+	}
+}
+
+// randCanonicalFp751Bytes returns fp751NumBytes bytes that canonically
+// encode some value in [0, p751), suitable as FromBytes input.
+func randCanonicalFp751Bytes(r *rand.Rand) []byte {
+	x := randFp751Element(r)
+
+	buf := make([]byte, fp751NumBytes)
+	fp751ToBytes(buf, &x)
+	return buf
+}
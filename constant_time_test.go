@@ -0,0 +1,137 @@
+package cln16sidh
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randPrimeFieldElement(r *rand.Rand) PrimeFieldElement {
+	var x PrimeFieldElement
+	for i := range x.a {
+		x.a[i] = r.Uint64()
+	}
+	return x
+}
+
+func randExtensionFieldElement(r *rand.Rand) ExtensionFieldElement {
+	a := randPrimeFieldElement(r)
+	b := randPrimeFieldElement(r)
+	return ExtensionFieldElement{a: a.a, b: b.a}
+}
+
+func TestFp751ElementConstantTimeEq(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 64; i++ {
+		x := randPrimeFieldElement(r).a
+		y := x
+		if x.ConstantTimeEq(&y) != 1 {
+			t.Fatalf("equal elements compared unequal")
+		}
+		y[i%fp751NumWords] ^= 1
+		if x.ConstantTimeEq(&y) != 0 {
+			t.Fatalf("unequal elements compared equal")
+		}
+	}
+}
+
+func TestPrimeFieldElementConstantTimeEq(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	x := randPrimeFieldElement(r)
+	y := x
+	if x.ConstantTimeEq(&y) != 1 {
+		t.Fatalf("equal elements compared unequal")
+	}
+	y.a[0] ^= 1
+	if x.ConstantTimeEq(&y) != 0 {
+		t.Fatalf("unequal elements compared equal")
+	}
+}
+
+func TestExtensionFieldElementConstantTimeEq(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	x := randExtensionFieldElement(r)
+	y := x
+	if x.ConstantTimeEq(&y) != 1 {
+		t.Fatalf("equal elements compared unequal")
+	}
+	y.b[0] ^= 1
+	if x.ConstantTimeEq(&y) != 0 {
+		t.Fatalf("unequal elements compared equal")
+	}
+}
+
+func TestPrimeFieldElementConditionalSelect(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	a := randPrimeFieldElement(r)
+	b := randPrimeFieldElement(r)
+
+	var dest PrimeFieldElement
+	dest.ConditionalSelect(&a, &b, 0)
+	if dest != a {
+		t.Fatalf("choice 0 did not select a")
+	}
+	dest.ConditionalSelect(&a, &b, 1)
+	if dest != b {
+		t.Fatalf("choice 1 did not select b")
+	}
+}
+
+func TestPrimeFieldElementConditionalSwap(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	a := randPrimeFieldElement(r)
+	b := randPrimeFieldElement(r)
+	origA, origB := a, b
+
+	a.ConditionalSwap(&b, 0)
+	if a != origA || b != origB {
+		t.Fatalf("choice 0 swapped values")
+	}
+	a.ConditionalSwap(&b, 1)
+	if a != origB || b != origA {
+		t.Fatalf("choice 1 did not swap values")
+	}
+}
+
+func TestExtensionFieldElementConditionalSelectAndSwap(t *testing.T) {
+	r := rand.New(rand.NewSource(6))
+	a := randExtensionFieldElement(r)
+	b := randExtensionFieldElement(r)
+
+	var dest ExtensionFieldElement
+	dest.ConditionalSelect(&a, &b, 0)
+	if dest != a {
+		t.Fatalf("choice 0 did not select a")
+	}
+	dest.ConditionalSelect(&a, &b, 1)
+	if dest != b {
+		t.Fatalf("choice 1 did not select b")
+	}
+
+	origA, origB := a, b
+	a.ConditionalSwap(&b, 1)
+	if a != origB || b != origA {
+		t.Fatalf("choice 1 did not swap values")
+	}
+}
+
+// TestConstantTimeEqDetectsFirstAndLastWordDifference checks that
+// ConstantTimeEq reports two elements as unequal whether they differ only in
+// the first word or only in the last word. It is not a timing test: it does
+// not measure running time or instruction count.
+func TestConstantTimeEqDetectsFirstAndLastWordDifference(t *testing.T) {
+	var x fp751Element
+	for i := range x {
+		x[i] = 0x1
+	}
+
+	// Differ only in the first word.
+	first := x
+	first[0] ^= 1
+	// Differ only in the last word.
+	last := x
+	last[fp751NumWords-1] ^= 1
+
+	if x.ConstantTimeEq(&first) != 0 || x.ConstantTimeEq(&last) != 0 {
+		t.Fatalf("expected both perturbations to compare unequal")
+	}
+}
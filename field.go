@@ -1,5 +1,7 @@
 package cln16sidh
 
+import "errors"
+
 //------------------------------------------------------------------------------
 // Extension Field
 //------------------------------------------------------------------------------
@@ -86,7 +88,7 @@ func (dest *ExtensionFieldElement) Inv(x *ExtensionFieldElement) *ExtensionField
 	// Now asq_plus_bsq = a^2 + b^2
 
 	var asq_plus_bsq_inv PrimeFieldElement
-	asq_plus_bsq_inv.Inv(&asq_plus_bsq)
+	asq_plus_bsq_inv.Inv(&asq_plus_bsq) // shares the powPMinus2 / P34 core with PrimeFieldElement.Inv
 	c := &asq_plus_bsq_inv.a
 
 	var ac fp751X2
@@ -102,6 +104,40 @@ func (dest *ExtensionFieldElement) Inv(x *ExtensionFieldElement) *ExtensionField
 	return dest
 }
 
+// BatchInvExtensionFieldElement sets dest[i] = 1/src[i] for every i, using
+// Montgomery's trick to replace n independent inversions with a single
+// inversion and 3(n-1) multiplications: first the running products
+// src[0]*...*src[i] are accumulated, then the product of all of src is
+// inverted once, and finally the individual inverses are recovered by
+// walking the running products backwards.
+//
+// dest and src must have the same length, and must not overlap.  No element
+// of src may be zero.
+func BatchInvExtensionFieldElement(dest, src []ExtensionFieldElement) {
+	n := len(src)
+	if len(dest) != n {
+		panic("BatchInvExtensionFieldElement: dest and src must have the same length")
+	}
+	if n == 0 {
+		return
+	}
+
+	partials := make([]ExtensionFieldElement, n)
+	partials[0] = src[0]
+	for i := 1; i < n; i++ {
+		partials[i].Mul(&partials[i-1], &src[i])
+	}
+
+	var inv ExtensionFieldElement
+	inv.Inv(&partials[n-1])
+
+	for i := n - 1; i > 0; i-- {
+		dest[i].Mul(&inv, &partials[i-1])
+		inv.Mul(&inv, &src[i])
+	}
+	dest[0] = inv
+}
+
 // Set dest = x * x
 //
 // Allowed to overlap dest with x.
@@ -159,6 +195,68 @@ func (lhs *ExtensionFieldElement) VartimeEq(rhs *ExtensionFieldElement) bool {
 	return lhs.a.vartimeEq(rhs.a) && lhs.b.vartimeEq(rhs.b)
 }
 
+// ConstantTimeEq returns 1 if lhs == rhs, and 0 otherwise.  Unlike VartimeEq,
+// the running time does not depend on the values being compared, so this is
+// safe to use on secret-dependent values.
+func (lhs *ExtensionFieldElement) ConstantTimeEq(rhs *ExtensionFieldElement) Choice {
+	return Choice(lhs.a.ConstantTimeEq(&rhs.a) & lhs.b.ConstantTimeEq(&rhs.b))
+}
+
+// ConditionalSelect sets dest = a if choice == 0, or dest = b if choice == 1,
+// without branching on choice.
+//
+// Returns dest to allow chaining operations.
+func (dest *ExtensionFieldElement) ConditionalSelect(a, b *ExtensionFieldElement, choice Choice) *ExtensionFieldElement {
+	dest.a.ConditionalSelect(&a.a, &b.a, choice)
+	dest.b.ConditionalSelect(&a.b, &b.b, choice)
+
+	return dest
+}
+
+// ConditionalSwap swaps the values of a and b if choice == 1, and leaves them
+// unchanged if choice == 0, without branching on choice.
+func (a *ExtensionFieldElement) ConditionalSwap(b *ExtensionFieldElement, choice Choice) {
+	a.a.ConditionalSwap(&b.a, choice)
+	a.b.ConditionalSwap(&b.b, choice)
+}
+
+// ToBytes writes the canonical little-endian encoding of x to dest, which
+// must be 188 bytes long.  The encoding is the concatenation of the
+// encodings of x.a and x.b, each of which is converted out of Montgomery
+// form before being packed.
+func (x *ExtensionFieldElement) ToBytes(dest []byte) {
+	if len(dest) != 188 {
+		panic("ExtensionFieldElement.ToBytes: dest must be 188 bytes")
+	}
+
+	a := PrimeFieldElement{a: x.a}
+	b := PrimeFieldElement{a: x.b}
+	a.ToBytes(dest[:94])
+	b.ToBytes(dest[94:])
+}
+
+// FromBytes sets dest to the value encoded in src, which must be the 188-byte
+// encoding produced by ToBytes.  It returns an error and leaves dest
+// unchanged if src does not canonically encode a value in [0,p) || [0,p).
+func (dest *ExtensionFieldElement) FromBytes(src []byte) error {
+	if len(src) != 188 {
+		return errors.New("ExtensionFieldElement.FromBytes: src must be 188 bytes")
+	}
+
+	var a, b PrimeFieldElement
+	if err := a.FromBytes(src[:94]); err != nil {
+		return err
+	}
+	if err := b.FromBytes(src[94:]); err != nil {
+		return err
+	}
+
+	dest.a = a.a
+	dest.b = b.a
+
+	return nil
+}
+
 //------------------------------------------------------------------------------
 // Prime Field
 //------------------------------------------------------------------------------
@@ -256,6 +354,64 @@ func (lhs *PrimeFieldElement) VartimeEq(rhs *PrimeFieldElement) bool {
 	return lhs.a.vartimeEq(rhs.a)
 }
 
+// ConstantTimeEq returns 1 if lhs == rhs, and 0 otherwise.  Unlike VartimeEq,
+// the running time does not depend on the values being compared, so this is
+// safe to use on secret-dependent values.
+func (lhs *PrimeFieldElement) ConstantTimeEq(rhs *PrimeFieldElement) Choice {
+	return lhs.a.ConstantTimeEq(&rhs.a)
+}
+
+// ConditionalSelect sets dest = a if choice == 0, or dest = b if choice == 1,
+// without branching on choice.
+//
+// Returns dest to allow chaining operations.
+func (dest *PrimeFieldElement) ConditionalSelect(a, b *PrimeFieldElement, choice Choice) *PrimeFieldElement {
+	dest.a.ConditionalSelect(&a.a, &b.a, choice)
+
+	return dest
+}
+
+// ConditionalSwap swaps the values of a and b if choice == 1, and leaves them
+// unchanged if choice == 0, without branching on choice.
+func (a *PrimeFieldElement) ConditionalSwap(b *PrimeFieldElement, choice Choice) {
+	a.a.ConditionalSwap(&b.a, choice)
+}
+
+// ToBytes writes the canonical little-endian encoding of x to dest, which
+// must be 94 bytes long.  The value is converted out of Montgomery form
+// before being packed.
+func (x *PrimeFieldElement) ToBytes(dest []byte) {
+	if len(dest) != fp751NumBytes {
+		panic("PrimeFieldElement.ToBytes: dest must be 94 bytes")
+	}
+
+	// x.a = a*R mod p; reduce by R^{-1} to recover a, then pack it.
+	var aR2 fp751X2
+	copy(aR2[:fp751NumWords], x.a[:])
+	var a fp751Element
+	fp751MontgomeryReduce(&a, &aR2)
+	fp751StrongReduce(&a)
+
+	fp751ToBytes(dest, &a)
+}
+
+// FromBytes sets dest to the value encoded in src, which must be the 94-byte
+// encoding produced by ToBytes.  It returns an error and leaves dest
+// unchanged if src is not the canonical encoding of a value in [0,p).
+func (dest *PrimeFieldElement) FromBytes(src []byte) error {
+	var a fp751Element
+	if err := fp751FromBytes(&a, src); err != nil {
+		return err
+	}
+
+	// a is in [0,p); convert it into Montgomery form.
+	var aRR fp751X2
+	fp751Mul(&aRR, &a, &montgomeryRsq)   // = a*R*R
+	fp751MontgomeryReduce(&dest.a, &aRR) // = a*R mod p
+
+	return nil
+}
+
 // Set dest = sqrt(x), if x is a square.  If x is nonsquare dest is undefined.
 //
 // Allowed to overlap x with dest.
@@ -277,6 +433,20 @@ func (dest *PrimeFieldElement) Sqrt(x *PrimeFieldElement) *PrimeFieldElement {
 //
 // Returns dest to allow chaining operations.
 func (dest *PrimeFieldElement) Inv(x *PrimeFieldElement) *PrimeFieldElement {
+	return dest.powPMinus2(x)
+}
+
+// Set dest = x^(p-2) = 1/x, by expressing the exponent as
+// (p-2) = 2*((p-3)/2) + 1 = 2*2*((p-3)/4) + 1 and running the P34 addition
+// chain once, on x^2.  PrimeFieldElement.Inv was already built this way; this is
+// just the same exponentiation pulled out under its own name so that
+// ExtensionFieldElement.Inv's reliance on it, via the norm a^2 + b^2, is
+// visible at the call site instead of implicit in Inv's body.
+//
+// Allowed to overlap x with dest.
+//
+// Returns dest to allow chaining operations.
+func (dest *PrimeFieldElement) powPMinus2(x *PrimeFieldElement) *PrimeFieldElement {
 	tmp_x := *x            // Copy x in case dest == x
 	dest.Square(x)         // dest = x^2
 	dest.P34(dest)         // dest = (x^2)^((p-3)/4) = x^((p-3)/2)
@@ -286,6 +456,40 @@ func (dest *PrimeFieldElement) Inv(x *PrimeFieldElement) *PrimeFieldElement {
 	return dest
 }
 
+// BatchInvPrimeFieldElement sets dest[i] = 1/src[i] for every i, using
+// Montgomery's trick to replace n independent inversions with a single
+// inversion and 3(n-1) multiplications: first the running products
+// src[0]*...*src[i] are accumulated, then the product of all of src is
+// inverted once, and finally the individual inverses are recovered by
+// walking the running products backwards.
+//
+// dest and src must have the same length, and must not overlap.  No element
+// of src may be zero.
+func BatchInvPrimeFieldElement(dest, src []PrimeFieldElement) {
+	n := len(src)
+	if len(dest) != n {
+		panic("BatchInvPrimeFieldElement: dest and src must have the same length")
+	}
+	if n == 0 {
+		return
+	}
+
+	partials := make([]PrimeFieldElement, n)
+	partials[0] = src[0]
+	for i := 1; i < n; i++ {
+		partials[i].Mul(&partials[i-1], &src[i])
+	}
+
+	var inv PrimeFieldElement
+	inv.Inv(&partials[n-1])
+
+	for i := n - 1; i > 0; i-- {
+		dest[i].Mul(&inv, &partials[i-1])
+		inv.Mul(&inv, &src[i])
+	}
+	dest[0] = inv
+}
+
 // Set dest = x^((p-3)/4)
 //
 // Allowed to overlap x with dest.
@@ -332,6 +536,10 @@ func (dest *PrimeFieldElement) P34(x *PrimeFieldElement) *PrimeFieldElement {
 
 const fp751NumWords = 12
 
+// The number of bytes in the canonical little-endian encoding of an
+// fp751Element: ceil(751/8) = 94.
+const fp751NumBytes = 94
+
 // (2^768) mod p.
 // This can't be a constant because Go doesn't allow array constants, so try
 // not to modify it.
@@ -353,46 +561,130 @@ type fp751Element [fp751NumWords]uint64
 // Represents an intermediate product of two elements of the base field F_p.
 type fp751X2 [2 * fp751NumWords]uint64
 
-// Compute z = x + y (mod p).
-//go:noescape
-func fp751AddReduced(z, x, y *fp751Element)
+// The low-level fp751* primitives (fp751AddReduced, fp751Mul,
+// fp751MontgomeryReduce, ...) have two homes: fp751_noasm.go, a pure-Go
+// implementation, and fp751_amd64.go, which declares them as hand-tuned
+// amd64 assembly that does not exist yet.  fp751_noasm.go is what every
+// build uses today, including plain `go build` on amd64; fp751_amd64.go
+// only takes over once built with the amd64asm tag, and won't build until
+// its assembly is written.  The two must produce bit-identical results.
+
+func (x fp751Element) vartimeEq(y fp751Element) bool {
+	fp751StrongReduce(&x)
+	fp751StrongReduce(&y)
+	eq := true
+	for i := 0; i < fp751NumWords; i++ {
+		eq = (x[i] == y[i]) && eq
+	}
+
+	return eq
+}
+
+// A Choice is the result of a constant-time comparison: 1 means true, 0 means
+// false.  Unlike a bool, a Choice is safe to use as a selector in constant
+// time without introducing secret-dependent branches.
+type Choice uint8
+
+// fp751EqMask returns a mask of all 1s if x == y (mod p), or all 0s if x != y
+// (mod p).  Both x and y are strongly reduced before comparison, so the mask
+// does not depend on which representative of the residue class is used.
+func fp751EqMask(x, y *fp751Element) uint64 {
+	xr := *x
+	yr := *y
+	fp751StrongReduce(&xr)
+	fp751StrongReduce(&yr)
+
+	var diff uint64
+	for i := 0; i < fp751NumWords; i++ {
+		diff |= xr[i] ^ yr[i]
+	}
 
-// Compute z = x - y (mod p).
-//go:noescape
-func fp751SubReduced(z, x, y *fp751Element)
+	// Fold diff down to its low bit: the result is 0 iff diff == 0.
+	diff |= diff >> 32
+	diff |= diff >> 16
+	diff |= diff >> 8
+	diff |= diff >> 4
+	diff |= diff >> 2
+	diff |= diff >> 1
 
-// Compute z = x + y, without reducing mod p.
-//go:noescape
-func fp751AddLazy(z, x, y *fp751Element)
+	return (diff & 1) - 1
+}
 
-// Compute z = x + y, without reducing mod p.
-//go:noescape
-func fp751X2AddLazy(z, x, y *fp751X2)
+// fp751Select sets dest[i] = a[i] if choice == 0, or dest[i] = b[i] if choice
+// == 1, without branching on choice.
+func fp751Select(dest, a, b *fp751Element, choice Choice) {
+	mask := uint64(0) - uint64(choice&1)
+	for i := 0; i < fp751NumWords; i++ {
+		dest[i] = a[i] ^ (mask & (a[i] ^ b[i]))
+	}
+}
 
-// Compute z = x - y, without reducing mod p.
-//go:noescape
-func fp751X2SubLazy(z, x, y *fp751X2)
+// fp751CondSwap swaps the contents of a and b if choice == 1, and leaves them
+// unchanged if choice == 0, without branching on choice.
+func fp751CondSwap(a, b *fp751Element, choice Choice) {
+	mask := uint64(0) - uint64(choice&1)
+	for i := 0; i < fp751NumWords; i++ {
+		t := mask & (a[i] ^ b[i])
+		a[i] ^= t
+		b[i] ^= t
+	}
+}
 
-// Compute z = x * y.
-//go:noescape
-func fp751Mul(z *fp751X2, x, y *fp751Element)
+// ConstantTimeEq returns 1 if x == y (mod p), and 0 otherwise.  Unlike
+// vartimeEq, the running time does not depend on the values being compared.
+func (x *fp751Element) ConstantTimeEq(y *fp751Element) Choice {
+	return Choice(fp751EqMask(x, y) & 1)
+}
 
-// Perform Montgomery reduction: set z = x R^{-1} (mod p).
-// Destroys the input value.
-//go:noescape
-func fp751MontgomeryReduce(z *fp751Element, x *fp751X2)
+// ConditionalSelect sets dest = a if choice == 0, or dest = b if choice == 1,
+// without branching on choice.
+func (dest *fp751Element) ConditionalSelect(a, b *fp751Element, choice Choice) {
+	fp751Select(dest, a, b, choice)
+}
 
-// Reduce a field element in [0, 2*p) to one in [0,p).
-//go:noescape
-func fp751StrongReduce(x *fp751Element)
+// ConditionalSwap swaps the values of a and b if choice == 1, and leaves them
+// unchanged if choice == 0, without branching on choice.
+func (a *fp751Element) ConditionalSwap(b *fp751Element, choice Choice) {
+	fp751CondSwap(a, b, choice)
+}
 
-func (x fp751Element) vartimeEq(y fp751Element) bool {
-	fp751StrongReduce(&x)
-	fp751StrongReduce(&y)
-	eq := true
+// fp751ToBytes packs x, which must already be strongly reduced to [0,p), into
+// dest as fp751NumBytes little-endian bytes.
+func fp751ToBytes(dest []byte, x *fp751Element) {
+	for i := 0; i < fp751NumBytes; i++ {
+		dest[i] = byte(x[i/8] >> uint(8*(i%8)))
+	}
+}
+
+// fp751FromBytes unpacks the fp751NumBytes little-endian bytes in src into
+// dest.  It returns an error and leaves dest unchanged if src is not the
+// canonical encoding of a value in [0,p), rejecting non-canonical encodings
+// in constant time.
+func fp751FromBytes(dest *fp751Element, src []byte) error {
+	if len(src) != fp751NumBytes {
+		return errors.New("fp751FromBytes: src must be fp751NumBytes bytes")
+	}
+
+	var raw fp751Element
+	for i := 0; i < fp751NumBytes; i++ {
+		raw[i/8] |= uint64(src[i]) << uint(8*(i%8))
+	}
+
+	// raw.ConstantTimeEq can't be used here: it strongly reduces both sides
+	// before comparing, which would make it agree that raw and its own
+	// reduction are equal even when raw was non-canonical to begin with.
+	// Compare the words directly instead.
+	reduced := raw
+	fp751StrongReduce(&reduced)
+	var diff uint64
 	for i := 0; i < fp751NumWords; i++ {
-		eq = (x[i] == y[i]) && eq
+		diff |= raw[i] ^ reduced[i]
+	}
+	if diff != 0 {
+		return errors.New("fp751FromBytes: src is not a canonical encoding of a field element")
 	}
 
-	return eq
+	*dest = raw
+
+	return nil
 }
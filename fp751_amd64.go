@@ -0,0 +1,44 @@
+//go:build amd64 && amd64asm
+
+// This file declares the fp751* primitives as hand-tuned amd64 assembly, to
+// be implemented in a sibling fp751_amd64.s once it exists.  No such
+// assembly exists in this tree yet, so this file is not built by default --
+// it only participates in the build when the amd64asm tag is explicitly
+// requested, and will fail to build until the .s file lands.  Until then,
+// fp751_noasm.go's pure-Go implementation is what every build, including
+// plain `go build` on amd64, actually uses.
+
+package cln16sidh
+
+// Compute z = x + y (mod p).
+//go:noescape
+func fp751AddReduced(z, x, y *fp751Element)
+
+// Compute z = x - y (mod p).
+//go:noescape
+func fp751SubReduced(z, x, y *fp751Element)
+
+// Compute z = x + y, without reducing mod p.
+//go:noescape
+func fp751AddLazy(z, x, y *fp751Element)
+
+// Compute z = x + y, without reducing mod p.
+//go:noescape
+func fp751X2AddLazy(z, x, y *fp751X2)
+
+// Compute z = x - y, without reducing mod p.
+//go:noescape
+func fp751X2SubLazy(z, x, y *fp751X2)
+
+// Compute z = x * y.
+//go:noescape
+func fp751Mul(z *fp751X2, x, y *fp751Element)
+
+// Perform Montgomery reduction: set z = x R^{-1} (mod p).
+// Destroys the input value.
+//go:noescape
+func fp751MontgomeryReduce(z *fp751Element, x *fp751X2)
+
+// Reduce a field element in [0, 2*p) to one in [0,p).
+//go:noescape
+func fp751StrongReduce(x *fp751Element)
@@ -0,0 +1,204 @@
+//go:build !amd64 || !amd64asm
+
+// This is the default build for every platform, including plain `go build`
+// on amd64: it only steps aside when the amd64asm tag is explicitly passed
+// to opt into fp751_amd64.go's (currently unimplemented) assembly backend.
+// See the comment there for why.
+
+package cln16sidh
+
+import "math/bits"
+
+// p751, the modulus of the base field, as fp751NumWords little-endian
+// 64-bit words.  p751 = 2^372 * 3^239 - 1.
+var fp751P = fp751Element{
+	0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff,
+	0xffffffffffffffff, 0xeeafffffffffffff, 0xe3ec968549f878a8, 0xda959b1a13f7cc76,
+	0x084e9867d6ebe876, 0x8562b5045cb25748, 0x0e12909f97badc66, 0x00006fe5d541f71c,
+}
+
+// p751 * 2^768 mod (2^1536), i.e. p751 shifted up by fp751NumWords words, so
+// that it can be added to an fp751X2 to correct an underflowed subtraction
+// without leaving the valid Montgomery-reduction input range.
+var fp751PR = fp751X2{
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff, 0xffffffffffffffff,
+	0xffffffffffffffff, 0xeeafffffffffffff, 0xe3ec968549f878a8, 0xda959b1a13f7cc76,
+	0x084e9867d6ebe876, 0x8562b5045cb25748, 0x0e12909f97badc66, 0x00006fe5d541f71c,
+}
+
+// addCarryAt ripples a carry of 1 into words starting at index idx, stopping
+// once it is absorbed or the slice is exhausted.
+func addCarryAt(words []uint64, idx int) {
+	for idx < len(words) {
+		sum, c := bits.Add64(words[idx], 1, 0)
+		words[idx] = sum
+		if c == 0 {
+			return
+		}
+		idx++
+	}
+}
+
+// fp751AddWords sets z = x + y as fp751NumWords-word integers, discarding
+// any carry out of the top word.
+func fp751AddWords(z, x, y *fp751Element) {
+	var carry uint64
+	for i := 0; i < fp751NumWords; i++ {
+		z[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+}
+
+// fp751SubWords sets z = x - y as fp751NumWords-word integers, and returns
+// the borrow out of the top word (1 if x < y, 0 otherwise).
+func fp751SubWords(z, x, y *fp751Element) uint64 {
+	var borrow uint64
+	for i := 0; i < fp751NumWords; i++ {
+		z[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	return borrow
+}
+
+// fp751CondSubP subtracts p751 from z if z >= p751, and leaves z unchanged
+// otherwise, without branching on the outcome.
+func fp751CondSubP(z *fp751Element) {
+	var reduced fp751Element
+	borrow := fp751SubWords(&reduced, z, &fp751P)
+	// borrow == 0 means z >= p751, so the subtraction applies.
+	mask := uint64(0) - (borrow ^ 1)
+	for i := 0; i < fp751NumWords; i++ {
+		z[i] = z[i] ^ (mask & (z[i] ^ reduced[i]))
+	}
+}
+
+// Compute z = x + y (mod p), for x and y in [0, 2*p); z is in [0,p).
+func fp751AddReduced(z, x, y *fp751Element) {
+	xr, yr := *x, *y
+	fp751CondSubP(&xr)
+	fp751CondSubP(&yr)
+	fp751AddWords(z, &xr, &yr)
+	fp751CondSubP(z)
+}
+
+// Compute z = x - y (mod p), for x and y in [0, 2*p); z is in [0,p).
+func fp751SubReduced(z, x, y *fp751Element) {
+	xr, yr := *x, *y
+	fp751CondSubP(&xr)
+	fp751CondSubP(&yr)
+	borrow := fp751SubWords(z, &xr, &yr)
+	var corrected fp751Element
+	fp751AddWords(&corrected, z, &fp751P)
+	mask := uint64(0) - borrow
+	for i := 0; i < fp751NumWords; i++ {
+		z[i] = z[i] ^ (mask & (z[i] ^ corrected[i]))
+	}
+}
+
+// Compute z = x + y, without reducing mod p.
+func fp751AddLazy(z, x, y *fp751Element) {
+	fp751AddWords(z, x, y)
+}
+
+// Compute z = x + y, without reducing mod p.
+func fp751X2AddLazy(z, x, y *fp751X2) {
+	var carry uint64
+	for i := 0; i < 2*fp751NumWords; i++ {
+		z[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+}
+
+// Compute z = x - y, without reducing mod p.
+func fp751X2SubLazy(z, x, y *fp751X2) {
+	var borrow uint64
+	var diff fp751X2
+	for i := 0; i < 2*fp751NumWords; i++ {
+		diff[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+
+	// If the subtraction underflowed, correct it by adding p751*2^768,
+	// which leaves the value congruent mod p while keeping it within the
+	// range a subsequent fp751MontgomeryReduce expects.
+	var corrected fp751X2
+	var carry uint64
+	for i := 0; i < 2*fp751NumWords; i++ {
+		corrected[i], carry = bits.Add64(diff[i], fp751PR[i], carry)
+	}
+
+	mask := uint64(0) - borrow
+	for i := 0; i < 2*fp751NumWords; i++ {
+		z[i] = diff[i] ^ (mask & (diff[i] ^ corrected[i]))
+	}
+}
+
+// Compute z = x * y.
+func fp751Mul(z *fp751X2, x, y *fp751Element) {
+	var result fp751X2
+
+	for i := 0; i < fp751NumWords; i++ {
+		var carry uint64
+		for j := 0; j < fp751NumWords; j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+
+			var c1, c2 uint64
+			lo, c1 = bits.Add64(lo, result[i+j], 0)
+			lo, c2 = bits.Add64(lo, carry, 0)
+			result[i+j] = lo
+
+			var c3 uint64
+			carry, c3 = bits.Add64(hi, c1, c2)
+			if c3 == 1 {
+				addCarryAt(result[:], i+j+2)
+			}
+		}
+
+		sum, c := bits.Add64(result[i+fp751NumWords], carry, 0)
+		result[i+fp751NumWords] = sum
+		if c == 1 {
+			addCarryAt(result[:], i+fp751NumWords+1)
+		}
+	}
+
+	*z = result
+}
+
+// Perform Montgomery reduction: set z = x R^{-1} (mod p).
+// Destroys the input value.
+//
+// p751 ≡ -1 (mod 2^64), so the Montgomery constant -p751^{-1} mod 2^64 is 1,
+// which is folded directly into the reduction loop below.
+func fp751MontgomeryReduce(z *fp751Element, x *fp751X2) {
+	for i := 0; i < fp751NumWords; i++ {
+		m := x[i] // = x[i] * (-p751^{-1} mod 2^64), since that constant is 1
+
+		var carry uint64
+		for j := 0; j < fp751NumWords; j++ {
+			hi, lo := bits.Mul64(m, fp751P[j])
+
+			var c1, c2 uint64
+			lo, c1 = bits.Add64(lo, x[i+j], 0)
+			lo, c2 = bits.Add64(lo, carry, 0)
+			x[i+j] = lo
+
+			var c3 uint64
+			carry, c3 = bits.Add64(hi, c1, c2)
+			if c3 == 1 {
+				addCarryAt(x[:], i+j+2)
+			}
+		}
+
+		k := i + fp751NumWords
+		for carry != 0 && k < 2*fp751NumWords {
+			var c uint64
+			x[k], c = bits.Add64(x[k], carry, 0)
+			carry = c
+			k++
+		}
+	}
+
+	copy(z[:], x[fp751NumWords:2*fp751NumWords])
+}
+
+// Reduce a field element in [0, 2*p) to one in [0,p).
+func fp751StrongReduce(x *fp751Element) {
+	fp751CondSubP(x)
+}
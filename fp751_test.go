@@ -0,0 +1,170 @@
+package cln16sidh
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// These tests exercise the low-level fp751* primitives directly against
+// math/big, independent of architecture. Today fp751_noasm.go is the only
+// backend that ever builds (see fp751_amd64.go's doc comment), so this
+// suite only runs against it; once real amd64 assembly lands behind the
+// amd64asm tag, these same vectors and helpers should be run against it
+// too, e.g. by duplicating this file's build tag to match fp751_amd64.go's,
+// to prove the two backends agree bit-for-bit.
+
+var bigP = fp751ElementToBig(&fp751P)
+
+// wordsToBig converts a little-endian slice of 64-bit words to a big.Int via
+// big-endian bytes, rather than big.Int.SetBits, since big.Word is
+// platform-word-sized (32 bits on 386/arm) and would silently truncate each
+// 64-bit limb there; bytes are a fixed size on every platform this runs on.
+func wordsToBig(words []uint64) *big.Int {
+	buf := make([]byte, len(words)*8)
+	for i, w := range words {
+		binary.BigEndian.PutUint64(buf[(len(words)-1-i)*8:], w)
+	}
+	return new(big.Int).SetBytes(buf)
+}
+
+func bigToWords(x *big.Int, words []uint64) {
+	buf := make([]byte, len(words)*8)
+	x.FillBytes(buf)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint64(buf[(len(words)-1-i)*8:])
+	}
+}
+
+func fp751ElementToBig(x *fp751Element) *big.Int {
+	return wordsToBig(x[:])
+}
+
+func fp751X2ToBig(x *fp751X2) *big.Int {
+	return wordsToBig(x[:])
+}
+
+func bigToFp751Element(x *big.Int) fp751Element {
+	var out fp751Element
+	bigToWords(x, out[:])
+	return out
+}
+
+// randFp751Element returns a uniformly random element of [0, p751), a range
+// every one of the fp751* primitives below accepts:
+// fp751MontgomeryReduce in particular requires its input to be the product
+// of two values less than p751, not two arbitrary 768-bit words, to stay
+// within the range a single Montgomery reduction pass can correct.
+func randFp751Element(r *rand.Rand) fp751Element {
+	var raw fp751Element
+	for i := range raw {
+		raw[i] = r.Uint64()
+	}
+	n := fp751ElementToBig(&raw)
+	n.Mod(n, bigP)
+	return bigToFp751Element(n)
+}
+
+func TestFp751MulMatchesBigInt(t *testing.T) {
+	r := rand.New(rand.NewSource(20))
+	for i := 0; i < 256; i++ {
+		x := randFp751Element(r)
+		y := randFp751Element(r)
+
+		var z fp751X2
+		fp751Mul(&z, &x, &y)
+
+		want := new(big.Int).Mul(fp751ElementToBig(&x), fp751ElementToBig(&y))
+		got := fp751X2ToBig(&z)
+		if want.Cmp(got) != 0 {
+			t.Fatalf("fp751Mul mismatch:\nx=%x\ny=%x\ngot =%s\nwant=%s", x, y, got, want)
+		}
+	}
+}
+
+func TestFp751MontgomeryReduceMatchesBigInt(t *testing.T) {
+	r := rand.New(rand.NewSource(21))
+	rModulus := new(big.Int).Lsh(big.NewInt(1), 768) // R = 2^768
+	rInv := new(big.Int).ModInverse(rModulus, bigP)
+
+	for i := 0; i < 256; i++ {
+		x := randFp751Element(r)
+		y := randFp751Element(r)
+
+		var xy fp751X2
+		fp751Mul(&xy, &x, &y)
+
+		var z fp751Element
+		fp751MontgomeryReduce(&z, &xy)
+		fp751StrongReduce(&z)
+
+		// z should equal (x*y) * R^-1 mod p.
+		want := new(big.Int).Mul(fp751ElementToBig(&x), fp751ElementToBig(&y))
+		want.Mul(want, rInv)
+		want.Mod(want, bigP)
+
+		got := fp751ElementToBig(&z)
+		if want.Cmp(got) != 0 {
+			t.Fatalf("fp751MontgomeryReduce mismatch:\nx=%x\ny=%x\ngot =%s\nwant=%s", x, y, got, want)
+		}
+	}
+}
+
+func TestFp751AddReducedMatchesBigInt(t *testing.T) {
+	r := rand.New(rand.NewSource(22))
+	for i := 0; i < 256; i++ {
+		x := randFp751Element(r)
+		y := randFp751Element(r)
+		fp751StrongReduce(&x)
+		fp751StrongReduce(&y)
+
+		var z fp751Element
+		fp751AddReduced(&z, &x, &y)
+
+		want := new(big.Int).Add(fp751ElementToBig(&x), fp751ElementToBig(&y))
+		want.Mod(want, bigP)
+		got := fp751ElementToBig(&z)
+		if want.Cmp(got) != 0 {
+			t.Fatalf("fp751AddReduced mismatch:\nx=%x\ny=%x\ngot =%s\nwant=%s", x, y, got, want)
+		}
+	}
+}
+
+func TestFp751SubReducedMatchesBigInt(t *testing.T) {
+	r := rand.New(rand.NewSource(23))
+	for i := 0; i < 256; i++ {
+		x := randFp751Element(r)
+		y := randFp751Element(r)
+		fp751StrongReduce(&x)
+		fp751StrongReduce(&y)
+
+		var z fp751Element
+		fp751SubReduced(&z, &x, &y)
+
+		want := new(big.Int).Sub(fp751ElementToBig(&x), fp751ElementToBig(&y))
+		want.Mod(want, bigP)
+		got := fp751ElementToBig(&z)
+		if want.Cmp(got) != 0 {
+			t.Fatalf("fp751SubReduced mismatch:\nx=%x\ny=%x\ngot =%s\nwant=%s", x, y, got, want)
+		}
+	}
+}
+
+func TestFp751StrongReduceIsIdempotentAndInRange(t *testing.T) {
+	r := rand.New(rand.NewSource(24))
+	for i := 0; i < 256; i++ {
+		x := randFp751Element(r)
+		fp751StrongReduce(&x)
+
+		if fp751ElementToBig(&x).Cmp(bigP) >= 0 {
+			t.Fatalf("strongly reduced value %x is not < p751", x)
+		}
+
+		y := x
+		fp751StrongReduce(&y)
+		if x != y {
+			t.Fatalf("fp751StrongReduce is not idempotent: %x != %x", x, y)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package sike is the intended home for a SIKE key-encapsulation mechanism
+// built on top of the field arithmetic in the parent package, plus a hybrid
+// X25519/SIKE key-exchange wrapper for integrators (e.g. TLS or DNSCrypt)
+// who want a single drop-in primitive.
+//
+// NONE OF THE SIKE-SPECIFIC CODE IN THIS PACKAGE WORKS YET. KeyGen,
+// Encapsulate and Decapsulate are placeholders that unconditionally return
+// ErrNotImplemented, because the curve/isogeny layer they depend on (point
+// arithmetic, Velu's formulas, strategy-based isogeny walks) does not exist
+// in this tree -- only the field arithmetic those algorithms would be built
+// on does. Every function that calls through to them, including the hybrid
+// helpers below, inherits that failure and is equally unusable today. This
+// package exists to fix the intended API surface ahead of that work, not to
+// offer a working KEM; do not import it expecting real post-quantum key
+// exchange.
+package sike
+
+import (
+	"crypto/ecdh"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+)
+
+// ErrNotImplemented is returned by KeyGen, Encapsulate and Decapsulate. See
+// the package doc comment: the curve/isogeny layer they depend on does not
+// exist in this tree yet, so these entry points are declared here to fix
+// the API surface, but cannot do real work until it lands.
+var ErrNotImplemented = errors.New("sike: isogeny layer not implemented")
+
+// PublicKey is the wire encoding of a SIKE public key.
+type PublicKey []byte
+
+// PrivateKey is the wire encoding of a SIKE private key.
+type PrivateKey []byte
+
+// Ciphertext is the wire encoding of a SIKE encapsulation.
+type Ciphertext []byte
+
+// SharedSecret is a SIKE-derived shared secret.
+type SharedSecret []byte
+
+// KeyGen is an unimplemented placeholder: it always returns
+// ErrNotImplemented. See the package doc comment.
+func KeyGen(rand io.Reader) (PublicKey, PrivateKey, error) {
+	return nil, nil, ErrNotImplemented
+}
+
+// Encapsulate is an unimplemented placeholder: it always returns
+// ErrNotImplemented. Once real, it will run the SIKE Fujisaki-Okamoto
+// encapsulation against pub, returning the ciphertext to send to the
+// holder of the matching private key and the shared secret agreed with
+// them, deriving that secret with cSHAKE256 as the transform's internal
+// KDF. See the package doc comment.
+func Encapsulate(rand io.Reader, pub PublicKey) (Ciphertext, SharedSecret, error) {
+	return nil, nil, ErrNotImplemented
+}
+
+// Decapsulate is an unimplemented placeholder: it always returns
+// ErrNotImplemented. Once real, it will recover the shared secret that
+// Encapsulate agreed on, given the private key sk and the ciphertext ct it
+// produced. See the package doc comment.
+func Decapsulate(sk PrivateKey, ct Ciphertext) (SharedSecret, error) {
+	return nil, ErrNotImplemented
+}
+
+//------------------------------------------------------------------------------
+// Hybrid X25519/SIKE key exchange
+//------------------------------------------------------------------------------
+//
+// NOT USABLE YET: every function below calls through to KeyGen, Encapsulate
+// or Decapsulate above and so always fails with ErrNotImplemented, even
+// though the X25519 half of each function is real, working code. They are
+// laid out now so that the only change needed once the SIKE entry points
+// are implemented is deleting this notice.
+
+// HybridPublicKey is an X25519/SIKE public key pair.
+type HybridPublicKey struct {
+	X25519 *ecdh.PublicKey
+	SIKE   PublicKey
+}
+
+// HybridPrivateKey is an X25519/SIKE private key pair.
+type HybridPrivateKey struct {
+	X25519 *ecdh.PrivateKey
+	SIKE   PrivateKey
+}
+
+// GenerateHybridKeyPair generates an X25519 keypair and a SIKE keypair.
+//
+// This will return ErrNotImplemented until KeyGen above has a real
+// implementation to call.
+func GenerateHybridKeyPair(rand io.Reader) (*HybridPublicKey, *HybridPrivateKey, error) {
+	xPriv, err := ecdh.X25519().GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sikePub, sikePriv, err := KeyGen(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pub := &HybridPublicKey{X25519: xPriv.PublicKey(), SIKE: sikePub}
+	priv := &HybridPrivateKey{X25519: xPriv, SIKE: sikePriv}
+
+	return pub, priv, nil
+}
+
+// HybridEncapsulate runs X25519 key exchange against pub.X25519 and SIKE
+// encapsulation against pub.SIKE, and combines the two shared secrets with
+// HKDF-Extract (RFC 5869) so that the result remains secure as long as
+// either primitive does. It returns the SIKE ciphertext and ephemeral
+// X25519 public key to send to the peer, along with the combined secret.
+//
+// This will return ErrNotImplemented until Encapsulate above has a real
+// implementation to call.
+func HybridEncapsulate(rand io.Reader, pub *HybridPublicKey) (ct Ciphertext, ephemeral *ecdh.PublicKey, secret []byte, err error) {
+	xPriv, err := ecdh.X25519().GenerateKey(rand)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	xSecret, err := xPriv.ECDH(pub.X25519)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	sikeCt, sikeSecret, err := Encapsulate(rand, pub.SIKE)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	secret = hkdfExtract(sha256.New, nil, append(xSecret, sikeSecret...))
+
+	return sikeCt, xPriv.PublicKey(), secret, nil
+}
+
+// HybridDecapsulate is the receiving side of HybridEncapsulate: given the
+// peer's ephemeral X25519 public key and the SIKE ciphertext it sent,
+// recover the same combined secret.
+//
+// This will return ErrNotImplemented until Decapsulate above has a real
+// implementation to call.
+func HybridDecapsulate(priv *HybridPrivateKey, peerX25519 *ecdh.PublicKey, ct Ciphertext) ([]byte, error) {
+	xSecret, err := priv.X25519.ECDH(peerX25519)
+	if err != nil {
+		return nil, err
+	}
+
+	sikeSecret, err := Decapsulate(priv.SIKE, ct)
+	if err != nil {
+		return nil, err
+	}
+
+	return hkdfExtract(sha256.New, nil, append(xSecret, sikeSecret...)), nil
+}
+
+// hkdfExtract is the RFC 5869 HKDF-Extract step: PRK = HMAC-Hash(salt, ikm).
+// A nil salt is replaced with a string of zeros the length of the hash, as
+// the RFC specifies.
+func hkdfExtract(hashNew func() hash.Hash, salt, ikm []byte) []byte {
+	if salt == nil {
+		salt = make([]byte, hashNew().Size())
+	}
+
+	mac := hmac.New(hashNew, salt)
+	mac.Write(ikm)
+
+	return mac.Sum(nil)
+}